@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Default backoff parameters, mirroring the gRPC connection-backoff
+// convention (https://github.com/grpc/grpc/blob/master/doc/connection-backoff.md)
+// used whenever RunConfig leaves the corresponding field unset.
+const (
+	defaultBaseDelay  = 1 * time.Second
+	defaultMaxDelay   = 120 * time.Second
+	defaultMultiplier = 1.6
+	defaultJitter     = 0.2
+)
+
+// computeBackoffDelay returns the delay to wait before retry attempt n
+// (n is 1 for the first retry, 2 for the second, and so on):
+//
+//	delay = min(baseDelay * multiplier^n, maxDelay)
+//
+// A random jitter factor in [1-jitter, 1+jitter] is then applied so that
+// concurrent workers don't retry in lockstep. A zero or negative
+// baseDelay, maxDelay or multiplier, and a negative jitter, fall back to
+// the gRPC default for that parameter; a jitter of zero disables jitter.
+func computeBackoffDelay(n int, baseDelay, maxDelay time.Duration, multiplier, jitter float64) time.Duration {
+	if baseDelay <= 0 {
+		baseDelay = defaultBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultMaxDelay
+	}
+	if multiplier <= 0 {
+		multiplier = defaultMultiplier
+	}
+	if jitter < 0 {
+		jitter = defaultJitter
+	}
+
+	delay := float64(baseDelay) * math.Pow(multiplier, float64(n))
+	if delay > float64(maxDelay) {
+		delay = float64(maxDelay)
+	}
+
+	delay *= 1 - jitter + 2*jitter*rand.Float64()
+
+	return time.Duration(delay)
+}
+
+// backoffForAttempt computes the retry delay before the given retry
+// attempt (1-indexed) using this worker's RunConfig.
+func (w *Worker) backoffForAttempt(attempt int) time.Duration {
+	return computeBackoffDelay(attempt, w.config.BaseDelay, w.config.MaxDelay, w.config.Multiplier, w.config.Jitter)
+}
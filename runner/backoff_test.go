@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeBackoffDelay(t *testing.T) {
+	// No jitter: delay is deterministic and follows base * multiplier^n,
+	// capped at maxDelay.
+	d := computeBackoffDelay(1, time.Second, 120*time.Second, 1.6, 0)
+	assert.Equal(t, time.Duration(1.6*float64(time.Second)), d)
+
+	d = computeBackoffDelay(2, time.Second, 120*time.Second, 1.6, 0)
+	assert.Equal(t, time.Duration(1.6*1.6*float64(time.Second)), d)
+
+	// Capped at maxDelay regardless of how large n grows.
+	d = computeBackoffDelay(50, time.Second, 10*time.Second, 1.6, 0)
+	assert.Equal(t, 10*time.Second, d)
+
+	// Jitter keeps the delay within [1-jitter, 1+jitter] of the
+	// unjittered value.
+	base := computeBackoffDelay(1, time.Second, 120*time.Second, 1.6, 0)
+	for i := 0; i < 50; i++ {
+		d := computeBackoffDelay(1, time.Second, 120*time.Second, 1.6, 0.2)
+		assert.GreaterOrEqual(t, float64(d), 0.8*float64(base))
+		assert.LessOrEqual(t, float64(d), 1.2*float64(base))
+	}
+
+	// Zero-valued delays and multiplier, and a negative jitter, fall back to
+	// the gRPC connection-backoff defaults; the default jitter keeps the
+	// delay within [1-defaultJitter, 1+defaultJitter] of base * multiplier.
+	d = computeBackoffDelay(1, 0, 0, 0, -1)
+	unjittered := defaultMultiplier * float64(defaultBaseDelay)
+	assert.GreaterOrEqual(t, float64(d), (1-defaultJitter)*unjittered)
+	assert.LessOrEqual(t, float64(d), (1+defaultJitter)*unjittered)
+}
@@ -20,6 +20,7 @@ import (
 type callTemplateData struct {
 	WorkerID           string // unique worker ID
 	RequestNumber      int64  // unique incremented request number for each request
+	AttemptNumber      int64  // attempt number for this request, starting at 1; increases on retry
 	FullyQualifiedName string // fully-qualified name of the method call
 	MethodName         string // shorter call method name
 	ServiceName        string // the service name
@@ -29,6 +30,10 @@ type callTemplateData struct {
 	IsServerStreaming  bool   // whether this call is server streaming
 	Timestamp          string // timestamp of the call in RFC3339 format
 	TimestampUnix      int64  // timestamp of the call as unix time
+
+	// Row holds the current row's values when the request's data comes
+	// from a CSV DataProvider, keyed by the CSV file's header column names.
+	Row map[string]string
 }
 
 // newCallTemplateData returns new call template data
@@ -40,6 +45,7 @@ func newCallTemplateData(mtd *desc.MethodDescriptor, workerID string, reqNum int
 	return &callTemplateData{
 		WorkerID:           workerID,
 		RequestNumber:      reqNum,
+		AttemptNumber:      1,
 		FullyQualifiedName: mtd.GetFullyQualifiedName(),
 		MethodName:         mtd.GetName(),
 		ServiceName:        mtd.GetService().GetName(),
@@ -53,6 +59,14 @@ func newCallTemplateData(mtd *desc.MethodDescriptor, workerID string, reqNum int
 }
 
 func (td *callTemplateData) execute(data string) (*bytes.Buffer, error) {
+	return td.executeContext(data, td)
+}
+
+// executeContext renders data as a Go template against context rather than
+// td itself, so callers that need to evaluate a template against an
+// augmented view of the call data (e.g. assertions evaluating against the
+// response) can still reuse td's template functions.
+func (td *callTemplateData) executeContext(data string, context interface{}) (*bytes.Buffer, error) {
 	t := template.Must(template.New("call_template_data").Funcs(template.FuncMap{
 		// Read all file content into string
 		"Read": func(file string) (string, error) {
@@ -166,7 +180,7 @@ func (td *callTemplateData) execute(data string) (*bytes.Buffer, error) {
 		},
 	}).Parse(data))
 	var tpl bytes.Buffer
-	err := t.Execute(&tpl, td)
+	err := t.Execute(&tpl, context)
 	return &tpl, err
 }
 
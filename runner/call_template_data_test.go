@@ -17,6 +17,7 @@ func TestCallTemplateData_New(t *testing.T) {
 	assert.NotNil(t, ctd)
 	assert.Equal(t, "worker_id_123", ctd.WorkerID)
 	assert.Equal(t, int64(100), ctd.RequestNumber)
+	assert.Equal(t, int64(1), ctd.AttemptNumber)
 	assert.Equal(t, "helloworld.Greeter.SayHello", ctd.FullyQualifiedName)
 	assert.Equal(t, "SayHello", ctd.MethodName)
 	assert.Equal(t, "Greeter", ctd.ServiceName)
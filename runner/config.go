@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// RunConfig holds the configuration for a single ghz run. It is built up
+// by the CLI / JSON config layer and consumed by Worker and the
+// channel/scheduler/provider construction in run.go.
+type RunConfig struct {
+	// qps is the target aggregate requests/second across all workers;
+	// 0 means unthrottled.
+	qps int
+	// binary selects binary (protobuf) payloads over JSON ones.
+	binary bool
+	// dryRun renders and prints the payload for each request instead of
+	// sending it.
+	dryRun bool
+	// timeout bounds a single attempt of a call; see MaxAttempts for how
+	// it interacts with retries.
+	timeout time.Duration
+	// data is the template (or static payload) used to build requests
+	// when no DataProvider is configured.
+	data []byte
+	// metadata is the template used to build outgoing gRPC metadata.
+	metadata []byte
+	// streamInterval paces messages sent on a client/bidi-streaming call.
+	streamInterval time.Duration
+
+	// MaxAttempts is the maximum number of times a single logical
+	// request is attempted, including the first try. Values < 1 are
+	// treated as 1 (no retries). Ignored for client/server-streaming
+	// methods, since bytes already sent can't be safely replayed. When
+	// timeout is set, all attempts of a request and the backoff delays
+	// between them share a budget of timeout * MaxAttempts.
+	MaxAttempts int
+	// BaseDelay, MaxDelay, Multiplier and Jitter parameterize the retry
+	// backoff; see computeBackoffDelay. Zero BaseDelay, MaxDelay and
+	// Multiplier, and a negative Jitter, fall back to the gRPC
+	// connection-backoff defaults; a zero Jitter disables jitter.
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+	Multiplier float64
+	Jitter     float64
+	// RetryableCodes overrides defaultRetryableCodes when non-empty.
+	RetryableCodes []codes.Code
+
+	// KeepaliveTime, KeepaliveTimeout and KeepalivePermitWithoutStream
+	// configure the channel's keepalive.ClientParameters.
+	KeepaliveTime                time.Duration
+	KeepaliveTimeout             time.Duration
+	KeepalivePermitWithoutStream bool
+	// MaxRecvMsgSize and MaxSendMsgSize bound message sizes; 0 means the
+	// grpc-go default.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+	// InitialWindowSize and InitialConnWindowSize set the HTTP/2 flow
+	// control window sizes for streams and the connection respectively;
+	// 0 means the grpc-go default.
+	InitialWindowSize     int32
+	InitialConnWindowSize int32
+
+	// LoadSchedule selects how request arrivals are paced across the
+	// run; see LoadScheduleType. The zero value behaves like
+	// LoadScheduleConst.
+	LoadSchedule LoadScheduleType
+	// LoadScheduleSteps configures the "step" schedule's QPS plateaus.
+	LoadScheduleSteps []ScheduleStep
+	// MaxDuration bounds the run by wall-clock time in addition to the
+	// total request count; workers stop at whichever limit comes first.
+	MaxDuration time.Duration
+
+	// DataProviderSpec, when set using the "@file.csv" / "@file.jsonl" /
+	// "@|cmd args" convention, selects an external DataProvider via
+	// newDataProviderFromSpec instead of the static data / arrayJSONData
+	// paths.
+	DataProviderSpec string
+	// DataProviderEOFBehavior controls what the selected DataProvider
+	// does once its source is exhausted.
+	DataProviderEOFBehavior EOFBehavior
+
+	// Assertions are evaluated against every response received; pass/fail
+	// counts are aggregated for the report. Empty disables assertions.
+	Assertions []Assertion
+	// ResponsesOutputFile, when set, persists received responses as JSONL
+	// to this path via a responseSampler.
+	ResponsesOutputFile string
+	// ResponsesSampleRate is the fraction (0, 1] of responses persisted to
+	// ResponsesOutputFile; 0 is treated as 1 (persist all of them).
+	ResponsesSampleRate float64
+}
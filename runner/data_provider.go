@@ -0,0 +1,308 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// EOFBehavior controls what a DataProvider does once its underlying
+// source is exhausted; EOFRandom also sets the order of rows before that.
+type EOFBehavior string
+
+const (
+	// EOFStop reports io.EOF once the source is exhausted.
+	EOFStop EOFBehavior = "stop"
+	// EOFWrap restarts from the beginning of the source.
+	EOFWrap EOFBehavior = "wrap"
+	// EOFRandom serves uniformly random rows. CSV and JSONL sources are
+	// sampled at random from the first request on (JSONL from a bounded
+	// reservoir of the lines read so far); a command's output is served in
+	// order until the command exits and at random from then on.
+	EOFRandom EOFBehavior = "random"
+)
+
+// DataProvider supplies the message payload for a single request. Every
+// worker may call Next concurrently, so implementations must be safe for
+// concurrent use and must atomically claim whichever row/line they hand out.
+type DataProvider interface {
+	Next(ctx context.Context, ctd *callTemplateData) ([]byte, error)
+}
+
+// newDataProviderFromSpec builds a DataProvider from the -d flag's value
+// when it uses the "@" external-source convention: "@file.csv" and
+// "@file.jsonl" select a provider by extension, and "@|cmd args" runs an
+// external command as the source. template is the original -d contents,
+// used verbatim as the per-row template for CSV sources. Plain JSON
+// payloads (no leading "@") aren't handled here; callers fall back to the
+// existing static/array JSON paths for those.
+func newDataProviderFromSpec(spec, template string, eof EOFBehavior) (DataProvider, error) {
+	if !strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("not an external data provider spec: %q", spec)
+	}
+
+	body := strings.TrimPrefix(spec, "@")
+
+	if strings.HasPrefix(body, "|") {
+		return newCmdDataProvider(strings.TrimPrefix(body, "|"), eof)
+	}
+
+	switch strings.ToLower(path.Ext(body)) {
+	case ".csv":
+		return newCSVDataProvider(body, template, eof)
+	case ".jsonl":
+		return newJSONLDataProvider(body, eof)
+	default:
+		return nil, fmt.Errorf("unrecognized data provider file extension: %q", body)
+	}
+}
+
+// nextIndex atomically claims the next row index out of n rows according
+// to the configured EOF behavior.
+func nextIndex(counter *int64, n int64, eof EOFBehavior) (int64, error) {
+	if n == 0 {
+		return 0, io.EOF
+	}
+
+	i := atomic.AddInt64(counter, 1) - 1
+
+	switch eof {
+	case EOFWrap:
+		return i % n, nil
+	case EOFRandom:
+		return rand.Int63n(n), nil
+	default: // EOFStop
+		if i >= n {
+			return 0, io.EOF
+		}
+		return i, nil
+	}
+}
+
+// csvDataProvider serves requests from a CSV file. The header row maps
+// column names to template variables exposed on callTemplateData.Row,
+// e.g. {{.Row.user_id}}. Rows are read entirely into memory at
+// construction time since random access is needed for wrap/random EOF
+// behavior.
+type csvDataProvider struct {
+	template string
+	rows     []map[string]string
+	next     int64
+	eof      EOFBehavior
+}
+
+func newCSVDataProvider(filePath, template string, eof EOFBehavior) (*csvDataProvider, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 1 {
+		return nil, fmt.Errorf("csv file %q has no header row", filePath)
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, col := range header {
+			if i < len(rec) {
+				row[col] = rec[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return &csvDataProvider{template: template, rows: rows, eof: eof}, nil
+}
+
+func (p *csvDataProvider) Next(ctx context.Context, ctd *callTemplateData) ([]byte, error) {
+	idx, err := nextIndex(&p.next, int64(len(p.rows)), p.eof)
+	if err != nil {
+		return nil, err
+	}
+
+	ctd.Row = p.rows[idx]
+
+	return []byte(p.template), nil
+}
+
+// jsonlDataProvider streams messages from a newline-delimited JSON file
+// without loading the whole corpus into memory, so multi-million row
+// files are fine. Concurrent Next calls are serialized behind a mutex
+// since the underlying reader must advance sequentially.
+type jsonlDataProvider struct {
+	mu  sync.Mutex
+	f   *os.File
+	r   *bufio.Reader
+	eof EOFBehavior
+
+	// reservoir and seen implement reservoir sampling for EOFRandom, so a
+	// "random" row can be served without ever loading the whole file into
+	// memory: reading continues (wrapping at EOF) to keep refreshing a
+	// fixed-size uniform sample of every line seen so far.
+	reservoir []string
+	seen      int64
+}
+
+// jsonlReservoirSize bounds the memory EOFRandom uses on a jsonlDataProvider,
+// independent of how large the underlying file is.
+const jsonlReservoirSize = 1000
+
+func newJSONLDataProvider(filePath string, eof EOFBehavior) (*jsonlDataProvider, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonlDataProvider{f: f, r: bufio.NewReader(f), eof: eof}, nil
+}
+
+func (p *jsonlDataProvider) Next(ctx context.Context, ctd *callTemplateData) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.eof == EOFRandom {
+		return p.nextRandom()
+	}
+
+	for {
+		line, err := p.r.ReadBytes('\n')
+		if err == nil || (err == io.EOF && len(line) > 0) {
+			return bytes.TrimRight(line, "\r\n"), nil
+		}
+		if err != io.EOF {
+			return nil, err
+		}
+
+		if p.eof == EOFStop {
+			return nil, io.EOF
+		}
+
+		// EOFWrap restarts the stream from the top.
+		if _, err := p.f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		p.r.Reset(p.f)
+	}
+}
+
+// nextRandom serves EOFRandom via reservoir sampling (Algorithm R):
+// the reservoir is filled from the first jsonlReservoirSize lines, then
+// each subsequent line read replaces a uniformly-chosen reservoir slot
+// with probability reservoirSize/seen, keeping the reservoir a uniform
+// sample of every line read so far while bounding memory use.
+func (p *jsonlDataProvider) nextRandom() ([]byte, error) {
+	for len(p.reservoir) < jsonlReservoirSize {
+		line, err := p.r.ReadBytes('\n')
+		if err == nil || (err == io.EOF && len(line) > 0) {
+			p.seen++
+			p.reservoir = append(p.reservoir, string(bytes.TrimRight(line, "\r\n")))
+			continue
+		}
+		if err != io.EOF {
+			return nil, err
+		}
+		if len(p.reservoir) == 0 {
+			return nil, io.EOF
+		}
+		break // the file has fewer lines than the reservoir can hold
+	}
+
+	// Keep pulling in new lines so a long-running sample eventually
+	// reflects the whole file rather than just its first rows.
+	line, err := p.r.ReadBytes('\n')
+	switch {
+	case err == nil || (err == io.EOF && len(line) > 0):
+		p.seen++
+		if idx := rand.Int63n(p.seen); idx < int64(len(p.reservoir)) {
+			p.reservoir[idx] = string(bytes.TrimRight(line, "\r\n"))
+		}
+	case err == io.EOF:
+		if _, serr := p.f.Seek(0, io.SeekStart); serr != nil {
+			return nil, serr
+		}
+		p.r.Reset(p.f)
+	default:
+		return nil, err
+	}
+
+	return []byte(p.reservoir[rand.Intn(len(p.reservoir))]), nil
+}
+
+// cmdDataProvider runs an external command once and serves one JSON
+// message per line of its stdout. Lines are buffered as they're read so
+// that wrap/random EOF behavior can replay them once the command exits.
+type cmdDataProvider struct {
+	mu    sync.Mutex
+	cmd   *exec.Cmd
+	r     *bufio.Reader
+	eof   EOFBehavior
+	lines []string
+	next  int64
+	done  bool
+}
+
+func newCmdDataProvider(commandLine string, eof EOFBehavior) (*cmdDataProvider, error) {
+	parts := strings.Fields(commandLine)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty data provider command")
+	}
+
+	cmd := exec.Command(parts[0], parts[1:]...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &cmdDataProvider{cmd: cmd, r: bufio.NewReader(stdout), eof: eof}, nil
+}
+
+func (p *cmdDataProvider) Next(ctx context.Context, ctd *callTemplateData) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.done {
+		line, err := p.r.ReadBytes('\n')
+		if err == nil || (err == io.EOF && len(line) > 0) {
+			line = bytes.TrimRight(line, "\r\n")
+			p.lines = append(p.lines, string(line))
+			return line, nil
+		}
+		if err != io.EOF {
+			return nil, err
+		}
+
+		p.done = true
+		_ = p.cmd.Wait()
+
+		if p.eof == EOFStop {
+			return nil, io.EOF
+		}
+	}
+
+	idx, err := nextIndex(&p.next, int64(len(p.lines)), p.eof)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(p.lines[idx]), nil
+}
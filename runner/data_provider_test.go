@@ -0,0 +1,138 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextIndex(t *testing.T) {
+	var counter int64
+
+	idx, err := nextIndex(&counter, 2, EOFStop)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), idx)
+
+	idx, err = nextIndex(&counter, 2, EOFStop)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), idx)
+
+	_, err = nextIndex(&counter, 2, EOFStop)
+	assert.Equal(t, io.EOF, err)
+
+	var wrapCounter int64
+	idx, err = nextIndex(&wrapCounter, 2, EOFWrap)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), idx)
+	idx, err = nextIndex(&wrapCounter, 2, EOFWrap)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), idx)
+	idx, err = nextIndex(&wrapCounter, 2, EOFWrap)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), idx)
+}
+
+func TestCSVDataProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.csv")
+	err := ioutil.WriteFile(path, []byte("user_id,name\n1,alice\n2,bob\n"), 0644)
+	assert.NoError(t, err)
+
+	p, err := newCSVDataProvider(path, `{"id":"{{.Row.user_id}}"}`, EOFStop)
+	assert.NoError(t, err)
+	assert.NotNil(t, p)
+
+	ctd := &callTemplateData{}
+
+	data, err := p.Next(context.Background(), ctd)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"{{.Row.user_id}}"}`, string(data))
+	assert.Equal(t, "1", ctd.Row["user_id"])
+	assert.Equal(t, "alice", ctd.Row["name"])
+
+	_, err = p.Next(context.Background(), ctd)
+	assert.NoError(t, err)
+	assert.Equal(t, "2", ctd.Row["user_id"])
+
+	_, err = p.Next(context.Background(), ctd)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestJSONLDataProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.jsonl")
+	err := ioutil.WriteFile(path, []byte("{\"a\":1}\n{\"a\":2}\n"), 0644)
+	assert.NoError(t, err)
+
+	p, err := newJSONLDataProvider(path, EOFWrap)
+	assert.NoError(t, err)
+	assert.NotNil(t, p)
+
+	ctd := &callTemplateData{}
+
+	first, err := p.Next(context.Background(), ctd)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(first))
+
+	second, err := p.Next(context.Background(), ctd)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":2}`, string(second))
+
+	// wraps back to the start once exhausted
+	third, err := p.Next(context.Background(), ctd)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(third))
+}
+
+func TestJSONLDataProvider_EOFRandom(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.jsonl")
+
+	var content string
+	for i := 0; i < 5; i++ {
+		content += fmt.Sprintf("{\"a\":%d}\n", i)
+	}
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	assert.NoError(t, err)
+
+	p, err := newJSONLDataProvider(path, EOFRandom)
+	assert.NoError(t, err)
+
+	ctd := &callTemplateData{}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		data, err := p.Next(context.Background(), ctd)
+		assert.NoError(t, err)
+		seen[string(data)] = true
+	}
+
+	// a genuinely random sample over 100 draws should surface more than
+	// just the first line, unlike the old wrap-disguised-as-random bug.
+	assert.Greater(t, len(seen), 1)
+	// the reservoir is bounded regardless of how small the source file is
+	assert.LessOrEqual(t, len(p.reservoir), jsonlReservoirSize)
+}
+
+func TestNewDataProviderFromSpec(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "data.csv")
+	err := ioutil.WriteFile(csvPath, []byte("user_id\n1\n"), 0644)
+	assert.NoError(t, err)
+
+	p, err := newDataProviderFromSpec("@"+csvPath, `{"id":"{{.Row.user_id}}"}`, EOFStop)
+	assert.NoError(t, err)
+	_, ok := p.(*csvDataProvider)
+	assert.True(t, ok)
+
+	_, err = newDataProviderFromSpec(`{"id":1}`, "", EOFStop)
+	assert.Error(t, err)
+
+	_, err = newDataProviderFromSpec("@"+filepath.Join(dir, "data.unknown"), "", EOFStop)
+	assert.Error(t, err)
+}
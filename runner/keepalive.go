@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// keepaliveDialOptions builds the grpc.DialOption slice for the channel's
+// keepalive behavior and message / window size limits, based on the
+// corresponding RunConfig fields. This lets a run reproduce a production
+// channel configuration, or keep long-lived streams alive through
+// intermediate proxies that would otherwise kill them.
+func keepaliveDialOptions(c *RunConfig) []grpc.DialOption {
+	var opts []grpc.DialOption
+
+	if c.KeepaliveTime > 0 || c.KeepaliveTimeout > 0 || c.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                c.KeepaliveTime,
+			Timeout:             c.KeepaliveTimeout,
+			PermitWithoutStream: c.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	var callOpts []grpc.CallOption
+	if c.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(c.MaxSendMsgSize))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	if c.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(c.InitialWindowSize))
+	}
+	if c.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(c.InitialConnWindowSize))
+	}
+
+	return opts
+}
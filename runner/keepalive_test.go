@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeepaliveDialOptions(t *testing.T) {
+	opts := keepaliveDialOptions(&RunConfig{})
+	assert.Empty(t, opts, "no options should be set when RunConfig has no keepalive/size/window fields configured")
+
+	opts = keepaliveDialOptions(&RunConfig{KeepalivePermitWithoutStream: true})
+	assert.Len(t, opts, 1, "keepalive params should be set even if only PermitWithoutStream is non-zero")
+
+	opts = keepaliveDialOptions(&RunConfig{MaxRecvMsgSize: 1024})
+	assert.Len(t, opts, 1)
+
+	opts = keepaliveDialOptions(&RunConfig{MaxSendMsgSize: 1024, MaxRecvMsgSize: 1024})
+	assert.Len(t, opts, 1, "recv and send size limits share a single WithDefaultCallOptions dial option")
+
+	opts = keepaliveDialOptions(&RunConfig{InitialWindowSize: 1 << 20})
+	assert.Len(t, opts, 1)
+
+	opts = keepaliveDialOptions(&RunConfig{InitialConnWindowSize: 1 << 20})
+	assert.Len(t, opts, 1)
+
+	opts = keepaliveDialOptions(&RunConfig{
+		KeepalivePermitWithoutStream: true,
+		MaxRecvMsgSize:               1024,
+		InitialWindowSize:            1 << 20,
+		InitialConnWindowSize:        1 << 20,
+	})
+	assert.Len(t, opts, 4)
+}
@@ -0,0 +1,176 @@
+package runner
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/jhump/protoreflect/dynamic"
+)
+
+// ResponseHandler lets callers observe every message received during a
+// run, independent of latency/stats recording. The same handler instance
+// is shared across all workers, and for bidi-streaming calls OnMessage is
+// called from the call's receiving goroutine rather than the worker's, so
+// implementations must be safe for concurrent use.
+type ResponseHandler interface {
+	// OnMessage is called once per response message received, including
+	// every message of a streaming call.
+	OnMessage(ctd *callTemplateData, msg *dynamic.Message)
+	// OnEnd is called once a call attempt has finished, successfully or not.
+	OnEnd(ctd *callTemplateData, err error)
+}
+
+// Assertion is a named predicate evaluated against every response
+// received. Expr is a Go template, evaluated with the usual
+// callTemplateData fields plus the response available as .reply, e.g.
+// `{{eq .reply.code 0}}`. The assertion passes when Expr renders to the
+// literal string "true".
+//
+// A Go template was chosen over a CEL expression so assertions reuse the
+// templating engine call_template_data.go already ships, rather than
+// pulling in a second expression language to do the same "evaluate
+// against a response" job.
+type Assertion struct {
+	Name string
+	Expr string
+}
+
+// evaluate renders a's template against msg and reports whether the
+// assertion passed.
+func (a *Assertion) evaluate(ctd *callTemplateData, msg *dynamic.Message) (bool, error) {
+	data := map[string]interface{}{}
+
+	ctdJSON, err := json.Marshal(ctd)
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(ctdJSON, &data); err != nil {
+		return false, err
+	}
+
+	if msg != nil {
+		msgJSON, err := msg.MarshalJSON()
+		if err != nil {
+			return false, err
+		}
+		var reply map[string]interface{}
+		if err := json.Unmarshal(msgJSON, &reply); err != nil {
+			return false, err
+		}
+		data["reply"] = reply
+	}
+
+	tpl, err := ctd.executeContext(a.Expr, data)
+	if err != nil {
+		return false, err
+	}
+
+	return tpl.String() == "true", nil
+}
+
+// assertionRecorder aggregates per-assertion pass/fail counts across a
+// run, for inclusion in the report output.
+type assertionRecorder struct {
+	mu     sync.Mutex
+	passed map[string]int64
+	failed map[string]int64
+}
+
+func newAssertionRecorder() *assertionRecorder {
+	return &assertionRecorder{
+		passed: make(map[string]int64),
+		failed: make(map[string]int64),
+	}
+}
+
+func (r *assertionRecorder) record(name string, passed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if passed {
+		r.passed[name]++
+	} else {
+		r.failed[name]++
+	}
+}
+
+// Counts returns the current pass/fail counts for name.
+func (r *assertionRecorder) Counts(name string) (passed, failed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.passed[name], r.failed[name]
+}
+
+// AssertionCount holds the pass/fail counts for a single named assertion.
+type AssertionCount struct {
+	Passed int64
+	Failed int64
+}
+
+// Snapshot returns the current pass/fail counts for every assertion that
+// has been evaluated at least once, keyed by assertion name.
+func (r *assertionRecorder) Snapshot() map[string]AssertionCount {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string]AssertionCount, len(r.passed)+len(r.failed))
+	for name, passed := range r.passed {
+		out[name] = AssertionCount{Passed: passed, Failed: r.failed[name]}
+	}
+	for name, failed := range r.failed {
+		if _, ok := out[name]; !ok {
+			out[name] = AssertionCount{Failed: failed}
+		}
+	}
+
+	return out
+}
+
+// responseSampler is a ResponseHandler that persists received responses
+// (or a sampled subset of them) to a JSONL file for offline diffing.
+type responseSampler struct {
+	mu     sync.Mutex
+	f      *os.File
+	enc    *json.Encoder
+	sample float64 // in (0, 1]; 1 persists every response
+}
+
+// newResponseSampler creates a responseSampler that writes to path,
+// persisting each response with probability sample (1 persists all of them).
+func newResponseSampler(path string, sample float64) (*responseSampler, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if sample <= 0 || sample > 1 {
+		sample = 1
+	}
+
+	return &responseSampler{f: f, enc: json.NewEncoder(f), sample: sample}, nil
+}
+
+func (s *responseSampler) OnMessage(ctd *callTemplateData, msg *dynamic.Message) {
+	if msg == nil || (s.sample < 1 && rand.Float64() > s.sample) {
+		return
+	}
+
+	raw, err := msg.MarshalJSON()
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.enc.Encode(json.RawMessage(raw))
+}
+
+func (s *responseSampler) OnEnd(ctd *callTemplateData, err error) {}
+
+// Close closes the underlying file.
+func (s *responseSampler) Close() error {
+	return s.f.Close()
+}
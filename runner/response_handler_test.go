@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bojand/ghz/protodesc"
+)
+
+func TestAssertion_Evaluate(t *testing.T) {
+	md, err := protodesc.GetMethodDescFromProto("helloworld.Greeter/SayHello", "../testdata/greeter.proto", []string{})
+	assert.NoError(t, err)
+	assert.NotNil(t, md)
+
+	ctd := newCallTemplateData(md, "worker_id_123", 1)
+
+	msg := dynamic.NewMessage(md.GetOutputType())
+	err = msg.TrySetFieldByName("message", "hello")
+	assert.NoError(t, err)
+
+	a := &Assertion{Name: "has-message", Expr: `{{eq .reply.message "hello"}}`}
+	passed, err := a.evaluate(ctd, msg)
+	assert.NoError(t, err)
+	assert.True(t, passed)
+
+	b := &Assertion{Name: "wrong-message", Expr: `{{eq .reply.message "bye"}}`}
+	passed, err = b.evaluate(ctd, msg)
+	assert.NoError(t, err)
+	assert.False(t, passed)
+}
+
+func TestAssertionRecorder(t *testing.T) {
+	r := newAssertionRecorder()
+
+	r.record("a", true)
+	r.record("a", true)
+	r.record("a", false)
+
+	passed, failed := r.Counts("a")
+	assert.Equal(t, int64(2), passed)
+	assert.Equal(t, int64(1), failed)
+}
+
+func TestAssertionRecorder_Snapshot(t *testing.T) {
+	r := newAssertionRecorder()
+
+	r.record("a", true)
+	r.record("a", false)
+	r.record("b", false)
+
+	assert.Equal(t, map[string]AssertionCount{
+		"a": {Passed: 1, Failed: 1},
+		"b": {Passed: 0, Failed: 1},
+	}, r.Snapshot())
+}
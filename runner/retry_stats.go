@@ -0,0 +1,53 @@
+package runner
+
+import "sync"
+
+// retryRecorder aggregates, across a run, how many attempts were made at
+// each attempt number and whether each attempt's error was intermediate (the
+// request went on to retry) or terminal (the attempt was the last one and
+// failed). The report uses this to show retry counts and a
+// terminal-vs-intermediate error breakdown.
+type retryRecorder struct {
+	mu           sync.Mutex
+	attempts     map[int64]int64 // attempt number -> occurrences
+	intermediate int64           // retryable errors that were retried
+	terminal     int64           // attempts that ended the logical request with an error
+}
+
+func newRetryRecorder() *retryRecorder {
+	return &retryRecorder{attempts: make(map[int64]int64)}
+}
+
+// recordAttempt records one attempt at the given attempt number. err is
+// the attempt's outcome and retried is true when the worker will make
+// another attempt after this one; intermediate/terminal are only tallied
+// when err is non-nil, since a successful attempt is neither.
+func (r *retryRecorder) recordAttempt(attempt int64, err error, retried bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts[attempt]++
+	if err == nil {
+		return
+	}
+	if retried {
+		r.intermediate++
+	} else {
+		r.terminal++
+	}
+}
+
+// Snapshot returns how many attempts were made at each attempt number (a
+// request that took three attempts counts once under 1, 2 and 3), plus
+// the total intermediate (retried) vs terminal error counts.
+func (r *retryRecorder) Snapshot() (attempts map[int64]int64, intermediate, terminal int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[int64]int64, len(r.attempts))
+	for k, v := range r.attempts {
+		out[k] = v
+	}
+
+	return out, r.intermediate, r.terminal
+}
@@ -0,0 +1,24 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryRecorder_RecordAttempt(t *testing.T) {
+	r := newRetryRecorder()
+
+	errBoom := errors.New("boom")
+
+	r.recordAttempt(1, errBoom, true)
+	r.recordAttempt(2, errBoom, true)
+	r.recordAttempt(3, errBoom, false)
+	r.recordAttempt(1, nil, false)
+
+	attempts, intermediate, terminal := r.Snapshot()
+	assert.Equal(t, map[int64]int64{1: 2, 2: 1, 3: 1}, attempts)
+	assert.Equal(t, int64(2), intermediate)
+	assert.Equal(t, int64(1), terminal)
+}
@@ -0,0 +1,215 @@
+package runner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"go.uber.org/multierr"
+	"google.golang.org/grpc"
+)
+
+// newClientConn dials target, applying the keepalive and message/window
+// size dial options derived from c ahead of any additional ones the
+// caller supplies (e.g. transport credentials, interceptors).
+func newClientConn(c *RunConfig, target string, extraOpts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	opts := append(keepaliveDialOptions(c), extraOpts...)
+	return grpc.Dial(target, opts...)
+}
+
+// qpsTick returns the per-worker ticker duration that, across
+// concurrency workers each ticking independently, produces an aggregate
+// rate of qps requests/second.
+func qpsTick(qps, concurrency int) time.Duration {
+	if qps <= 0 || concurrency <= 0 {
+		return 0
+	}
+
+	perWorkerQPS := float64(qps) / float64(concurrency)
+	if perWorkerQPS <= 0 {
+		return 0
+	}
+
+	return time.Duration(float64(time.Second) / perWorkerQPS)
+}
+
+// Requester owns a run's shared state - the channel, the method being
+// called, and the workers that execute it - and drives them to completion.
+type Requester struct {
+	config  *RunConfig
+	mtd     *desc.MethodDescriptor
+	cc      *grpc.ClientConn
+	stopCh  chan bool
+	workers []*Worker
+
+	retryRecorder     *retryRecorder
+	assertionRecorder *assertionRecorder
+	responseSampler   *responseSampler
+}
+
+// NewRequester dials target and builds the concurrency workers that will
+// execute n total requests against mtd using config c. extraOpts are
+// appended after the dial options derived from c (keepalive, message and
+// window sizes), so callers can add transport credentials, interceptors,
+// etc.
+func NewRequester(c *RunConfig, mtd *desc.MethodDescriptor, target string, n, concurrency int, extraOpts ...grpc.DialOption) (*Requester, error) {
+	cc, err := newClientConn(c, target, extraOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	stub := grpcdynamic.NewStub(cc)
+	stopCh := make(chan bool)
+	retries := newRetryRecorder()
+
+	// A single scheduler goroutine paces every worker so the aggregate
+	// arrival rate matches the configured schedule regardless of
+	// concurrency; LoadScheduleConst (the zero value) without MaxDuration
+	// keeps each worker ticking independently instead, which is equivalent
+	// for that mode and avoids the extra goroutine when nothing needs
+	// centralizing.
+	var sched *scheduler
+	if c.LoadSchedule == LoadSchedulePoisson || c.LoadSchedule == LoadScheduleStep || c.MaxDuration > 0 {
+		sched = newScheduler(c, stopCh)
+	}
+
+	// DataProviderSpec opts into the "-d @file.csv" / "@file.jsonl" /
+	// "@|cmd" convention; every worker shares the same provider instance
+	// so each safely claims its own next row/line.
+	var provider DataProvider
+	if c.DataProviderSpec != "" {
+		provider, err = newDataProviderFromSpec(c.DataProviderSpec, string(c.data), c.DataProviderEOFBehavior)
+		if err != nil {
+			cc.Close()
+			return nil, err
+		}
+	}
+
+	// Assertions, when configured, are aggregated into a single recorder
+	// shared by every worker so the report reflects the whole run.
+	var assertions *assertionRecorder
+	if len(c.Assertions) > 0 {
+		assertions = newAssertionRecorder()
+	}
+
+	// ResponsesOutputFile opts into persisting received responses (or a
+	// sampled subset) to a JSONL file via the ResponseHandler hook.
+	var sampler *responseSampler
+	if c.ResponsesOutputFile != "" {
+		sampler, err = newResponseSampler(c.ResponsesOutputFile, c.ResponsesSampleRate)
+		if err != nil {
+			cc.Close()
+			return nil, err
+		}
+	}
+
+	var responseHandler ResponseHandler
+	if sampler != nil {
+		responseHandler = sampler
+	}
+
+	var reqCounter int64
+
+	workers := make([]*Worker, concurrency)
+	for i := range workers {
+		nReq := n / concurrency
+		if i < n%concurrency {
+			nReq++
+		}
+
+		workers[i] = &Worker{
+			stub:              stub,
+			mtd:               mtd,
+			config:            c,
+			stopCh:            stopCh,
+			qpsTick:           qpsTick(c.qps, concurrency),
+			reqCounter:        &reqCounter,
+			nReq:              nReq,
+			workerID:          fmt.Sprintf("worker-%d", i),
+			retryRecorder:     retries,
+			schedule:          sched,
+			dataProvider:      provider,
+			responseHandler:   responseHandler,
+			assertions:        c.Assertions,
+			assertionRecorder: assertions,
+		}
+	}
+
+	return &Requester{
+		config:            c,
+		mtd:               mtd,
+		cc:                cc,
+		stopCh:            stopCh,
+		workers:           workers,
+		retryRecorder:     retries,
+		assertionRecorder: assertions,
+		responseSampler:   sampler,
+	}, nil
+}
+
+// Run executes the configured requests across all workers and returns
+// their aggregated error, if any.
+func (r *Requester) Run() error {
+	var wg sync.WaitGroup
+	errs := make(chan error, len(r.workers))
+
+	for _, w := range r.workers {
+		wg.Add(1)
+		go func(w *Worker) {
+			defer wg.Done()
+			errs <- w.runWorker()
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	var err error
+	for e := range errs {
+		err = multierr.Append(err, e)
+	}
+
+	return err
+}
+
+// Stop signals all workers to stop before they've sent their configured
+// number of requests.
+func (r *Requester) Stop() {
+	close(r.stopCh)
+}
+
+// RetryStats returns how many attempts were made at each attempt number,
+// plus the total intermediate (retried) vs terminal error counts across the run, so
+// the report can show retry counts.
+func (r *Requester) RetryStats() (attempts map[int64]int64, intermediate, terminal int64) {
+	return r.retryRecorder.Snapshot()
+}
+
+// AssertionStats returns the current pass/fail counts for every configured
+// assertion that has been evaluated at least once, keyed by assertion
+// name, so the report can aggregate a per-assertion pass/fail breakdown.
+// It returns nil when no assertions are configured for the run.
+func (r *Requester) AssertionStats() map[string]AssertionCount {
+	if r.assertionRecorder == nil {
+		return nil
+	}
+
+	return r.assertionRecorder.Snapshot()
+}
+
+// Close releases the underlying channel and, if responses are being
+// persisted to a file, flushes and closes it too.
+func (r *Requester) Close() error {
+	var err error
+	if r.responseSampler != nil {
+		err = r.responseSampler.Close()
+	}
+
+	if cerr := r.cc.Close(); cerr != nil {
+		err = cerr
+	}
+
+	return err
+}
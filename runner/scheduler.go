@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// LoadScheduleType selects how request arrivals are paced across a run.
+type LoadScheduleType string
+
+const (
+	// LoadScheduleConst paces requests at a fixed QPS using a uniform ticker.
+	LoadScheduleConst LoadScheduleType = "const"
+	// LoadSchedulePoisson paces requests as a Poisson process with the
+	// configured QPS as its rate, producing bursty inter-arrival times
+	// instead of a perfectly uniform ticker.
+	LoadSchedulePoisson LoadScheduleType = "poisson"
+	// LoadScheduleStep switches between fixed QPS plateaus at configured
+	// offsets into the run.
+	LoadScheduleStep LoadScheduleType = "step"
+)
+
+// ScheduleStep is one plateau of a "step" load schedule: once After has
+// elapsed since the run started, the scheduler paces at QPS.
+type ScheduleStep struct {
+	After time.Duration
+	QPS   int
+}
+
+// scheduler centralizes request pacing for a run. A single scheduler
+// goroutine feeds a shared channel that every worker pulls from, so the
+// aggregate arrival rate matches the target schedule regardless of how
+// many concurrent workers (-c) are consuming it.
+type scheduler struct {
+	tickCh chan struct{}
+	doneCh chan struct{}
+}
+
+// newScheduler starts the scheduler goroutine for the given RunConfig and
+// returns a scheduler that workers pull request slots from via next().
+// The goroutine stops once stopCh is closed or, if config.MaxDuration is
+// set, once that wall-clock duration elapses.
+func newScheduler(c *RunConfig, stopCh chan bool) *scheduler {
+	s := &scheduler{
+		tickCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+
+	go s.run(c, stopCh)
+
+	return s
+}
+
+// next blocks until the scheduler releases the next request slot, or
+// returns false if the run has stopped.
+func (s *scheduler) next() bool {
+	select {
+	case _, ok := <-s.tickCh:
+		return ok
+	case <-s.doneCh:
+		return false
+	}
+}
+
+func (s *scheduler) run(c *RunConfig, stopCh chan bool) {
+	defer close(s.tickCh)
+	defer close(s.doneCh)
+
+	start := time.Now()
+
+	for {
+		if c.MaxDuration > 0 && time.Since(start) >= c.MaxDuration {
+			return
+		}
+
+		qps := c.qps
+		if c.LoadSchedule == LoadScheduleStep && len(c.LoadScheduleSteps) > 0 {
+			qps = currentStepQPS(c.LoadScheduleSteps, c.qps, time.Since(start))
+		}
+
+		var wait time.Duration
+		if qps > 0 {
+			if c.LoadSchedule == LoadSchedulePoisson {
+				wait = poissonInterval(qps)
+			} else {
+				wait = time.Second / time.Duration(qps)
+			}
+		}
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-stopCh:
+				timer.Stop()
+				return
+			}
+		}
+
+		select {
+		case s.tickCh <- struct{}{}:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// poissonInterval draws an inter-arrival gap for a Poisson process with
+// rate qps (requests/sec): -ln(1-U)/qps, U ~ Uniform(0,1).
+func poissonInterval(qps int) time.Duration {
+	u := rand.Float64()
+	seconds := -math.Log(1-u) / float64(qps)
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// currentStepQPS returns the QPS in effect at elapsed time t, given a
+// step schedule sorted by ascending After and the base QPS to use before
+// the first step's threshold is reached.
+func currentStepQPS(steps []ScheduleStep, base int, t time.Duration) int {
+	qps := base
+	for _, step := range steps {
+		if t >= step.After {
+			qps = step.QPS
+		} else {
+			break
+		}
+	}
+	return qps
+}
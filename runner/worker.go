@@ -12,9 +12,20 @@ import (
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
 	"go.uber.org/multierr"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// defaultRetryableCodes are the gRPC status codes treated as transient and
+// eligible for retry when RunConfig.RetryableCodes is left empty.
+var defaultRetryableCodes = []codes.Code{
+	codes.Unavailable,
+	codes.ResourceExhausted,
+	codes.DeadlineExceeded,
+	codes.Aborted,
+}
+
 // Worker is used for doing a single stream of requests in parallel
 type Worker struct {
 	stub grpcdynamic.Stub
@@ -27,16 +38,68 @@ type Worker struct {
 	nReq       int
 	workerID   string
 
+	// schedule is the centralized pacer shared across all of a run's
+	// workers. When set it takes precedence over qpsTick so the aggregate
+	// arrival rate matches the target schedule regardless of concurrency.
+	schedule *scheduler
+
 	// cached messages only for binary
 	cachedMessages []*dynamic.Message
 
 	// non-binary json optimization
 	arrayJSONData []string
+
+	// dataProvider optionally supplies request payloads from an external
+	// source (CSV, JSONL, or command), taking precedence over data /
+	// arrayJSONData when set.
+	dataProvider DataProvider
+
+	// responseHandler, when set, is notified of every response message and
+	// of the end of every call attempt.
+	responseHandler ResponseHandler
+
+	// assertions are evaluated against every response received; results
+	// are aggregated into assertionRecorder for the report.
+	assertions        []Assertion
+	assertionRecorder *assertionRecorder
+
+	// retryRecorder, when set, is told the outcome of every retry attempt
+	// so the report can show retry counts and terminal-vs-intermediate
+	// error breakdowns.
+	retryRecorder *retryRecorder
+}
+
+// observeMessage notifies the configured ResponseHandler of a received
+// message and evaluates any configured assertions against it.
+func (w *Worker) observeMessage(ctd *callTemplateData, msg *dynamic.Message) {
+	if w.responseHandler != nil {
+		w.responseHandler.OnMessage(ctd, msg)
+	}
+
+	if w.assertionRecorder == nil {
+		return
+	}
+
+	for _, a := range w.assertions {
+		passed, err := a.evaluate(ctd, msg)
+		if err != nil {
+			passed = false
+		}
+		w.assertionRecorder.record(a.Name, passed)
+	}
+}
+
+// observeEnd notifies the configured ResponseHandler that a call attempt
+// has finished.
+func (w *Worker) observeEnd(ctd *callTemplateData, err error) {
+	if w.responseHandler != nil {
+		w.responseHandler.OnEnd(ctd, err)
+	}
 }
 
 func (w *Worker) runWorker() error {
 	var throttle <-chan time.Time
-	if w.config.qps > 0 {
+	if w.schedule == nil && w.config.qps > 0 {
 		throttle = time.Tick(w.qpsTick)
 	}
 
@@ -47,7 +110,11 @@ func (w *Worker) runWorker() error {
 		case <-w.stopCh:
 			return nil
 		default:
-			if w.config.qps > 0 {
+			if w.schedule != nil {
+				if !w.schedule.next() {
+					return err
+				}
+			} else if w.config.qps > 0 {
 				<-throttle
 			}
 
@@ -68,13 +135,22 @@ func (w *Worker) makeRequest() error {
 	var inputs []*dynamic.Message
 	var err error
 
-	// try the optimized path for JSON data for non client-streaming
-	if !w.config.binary && !w.mtd.IsClientStreaming() && len(w.arrayJSONData) > 0 {
+	switch {
+	case w.dataProvider != nil:
+		data, perr := w.dataProvider.Next(context.Background(), ctd)
+		if perr != nil {
+			return perr
+		}
+		if inputs, err = w.getMessages(ctd, data); err != nil {
+			return err
+		}
+	case !w.config.binary && !w.mtd.IsClientStreaming() && len(w.arrayJSONData) > 0:
+		// try the optimized path for JSON data for non client-streaming
 		indx := int((reqNum - 1) % int64(len(w.arrayJSONData))) // we want to start from inputs[0] so dec reqNum
 		if inputs, err = w.getMessages(ctd, []byte(w.arrayJSONData[indx])); err != nil {
 			return err
 		}
-	} else {
+	default:
 		if inputs, err = w.getMessages(ctd, w.config.data); err != nil {
 			return err
 		}
@@ -84,60 +160,160 @@ func (w *Worker) makeRequest() error {
         return nil
     }
 
-	mdMap, err := ctd.executeMetadata(string(w.config.metadata))
-	if err != nil {
-		return err
-	}
+	// Bytes sent over a client/server-streaming call can't be safely
+	// replayed, so retries only ever apply to the plain unary path.
+	streaming := w.mtd.IsClientStreaming() || w.mtd.IsServerStreaming()
 
-	var reqMD *metadata.MD
-	if mdMap != nil && len(*mdMap) > 0 {
-		md := metadata.New(*mdMap)
-		reqMD = &md
+	maxAttempts := w.config.MaxAttempts
+	if maxAttempts < 1 || streaming {
+		maxAttempts = 1
 	}
 
-	ctx := context.Background()
-	var cancel context.CancelFunc
-
+	// The shared budget bounds the logical request as a whole; each
+	// attempt gets min(w.config.timeout, time left in the budget), and
+	// backoff sleeps are deducted from the same budget rather than coming
+	// on top of it, so a slow run of retries can't hand a later attempt
+	// an already-expired context.
+	budget := w.config.timeout * time.Duration(maxAttempts)
+	parentCtx := context.Background()
 	if w.config.timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, w.config.timeout)
-	} else {
-		ctx, cancel = context.WithCancel(ctx)
+		var parentCancel context.CancelFunc
+		parentCtx, parentCancel = context.WithTimeout(parentCtx, budget)
+		defer parentCancel()
 	}
-	defer cancel()
 
-	// include the metadata
-	if reqMD != nil {
-		ctx = metadata.NewOutgoingContext(ctx, *reqMD)
+	start := time.Now()
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		ctd.AttemptNumber = int64(attempt)
+
+		if attempt > 1 {
+			remaining := budget - time.Since(start)
+			if w.config.timeout > 0 && remaining <= 0 {
+				break
+			}
+
+			delay := w.backoffForAttempt(attempt - 1)
+			if w.config.timeout > 0 && delay > remaining {
+				delay = remaining
+			}
+			time.Sleep(delay)
+		}
+
+		attemptTimeout := w.config.timeout
+		if w.config.timeout > 0 {
+			if remaining := budget - time.Since(start); remaining < attemptTimeout {
+				attemptTimeout = remaining
+			}
+			if attemptTimeout <= 0 {
+				break
+			}
+		}
+
+		ctx := parentCtx
+		var cancel context.CancelFunc
+		if w.config.timeout > 0 {
+			ctx, cancel = context.WithTimeout(parentCtx, attemptTimeout)
+		} else {
+			ctx, cancel = context.WithCancel(parentCtx)
+		}
+
+		// Metadata is re-rendered every attempt, unlike inputs (which can't
+		// safely be re-templated once bytes may already be in flight), so
+		// templates like `{{.AttemptNumber}}` can tag retried calls.
+		mdMap, mdErr := ctd.executeMetadata(string(w.config.metadata))
+		if mdErr != nil {
+			cancel()
+			return mdErr
+		}
+		if mdMap != nil && len(*mdMap) > 0 {
+			ctx = metadata.NewOutgoingContext(ctx, metadata.New(*mdMap))
+		}
+
+		err = w.invokeOnce(&ctx, ctd, inputs, reqNum)
+
+		w.observeEnd(ctd, err)
+
+		cancel()
+
+		willRetry := attempt < maxAttempts && w.isRetryableError(err)
+		if w.retryRecorder != nil {
+			w.retryRecorder.recordAttempt(ctd.AttemptNumber, err, willRetry)
+		}
+
+		if !willRetry {
+			break
+		}
 	}
 
-	// RPC errors are handled via stats handler
+	return err
+}
 
-	if w.mtd.IsClientStreaming() && w.mtd.IsServerStreaming() {
-		_ = w.makeBidiRequest(&ctx, inputs)
+// invokeOnce dispatches a single attempt of the call to the appropriate
+// gRPC invocation based on the method's streaming type.
+func (w *Worker) invokeOnce(ctx *context.Context, ctd *callTemplateData, inputs []*dynamic.Message, reqNum int64) error {
+	switch {
+	case w.mtd.IsClientStreaming() && w.mtd.IsServerStreaming():
+		return w.makeBidiRequest(ctx, ctd, inputs)
+	case w.mtd.IsClientStreaming():
+		return w.makeClientStreamingRequest(ctx, ctd, inputs)
+	case w.mtd.IsServerStreaming():
+		inputsLen := len(inputs)
+		if inputsLen == 0 {
+			return fmt.Errorf("no data provided for request")
+		}
+		inputIdx := int((reqNum - 1) % int64(inputsLen)) // we want to start from inputs[0] so dec reqNum
+		return w.makeServerStreamingRequest(ctx, ctd, inputs[inputIdx])
+	default:
+		inputsLen := len(inputs)
+		if inputsLen == 0 {
+			return fmt.Errorf("no data provided for request")
+		}
+		inputIdx := int((reqNum - 1) % int64(inputsLen)) // we want to start from inputs[0] so dec reqNum
+		resp, err := w.stub.InvokeRpc(*ctx, w.mtd, inputs[inputIdx])
+		if err == nil {
+			if dm, ok := resp.(*dynamic.Message); ok {
+				w.observeMessage(ctd, dm)
+			}
+		}
+		return err
 	}
-	if w.mtd.IsClientStreaming() {
-		_ = w.makeClientStreamingRequest(&ctx, inputs)
+}
+
+// isRetryableError reports whether err's gRPC status code is configured
+// as retryable, falling back to defaultRetryableCodes when RunConfig
+// doesn't specify its own list.
+func (w *Worker) isRetryableError(err error) bool {
+	if err == nil {
+		return false
 	}
 
-	inputsLen := len(inputs)
-	if inputsLen == 0 {
-		return fmt.Errorf("no data provided for request")
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
 	}
-	inputIdx := int((reqNum - 1) % int64(inputsLen)) // we want to start from inputs[0] so dec reqNum
 
-	if w.mtd.IsServerStreaming() {
-		_ = w.makeServerStreamingRequest(&ctx, inputs[inputIdx])
+	retryableCodes := defaultRetryableCodes
+	if len(w.config.RetryableCodes) > 0 {
+		retryableCodes = w.config.RetryableCodes
 	}
-	// TODO: handle response?
-	_, _ = w.stub.InvokeRpc(ctx, w.mtd, inputs[inputIdx])
 
-	return err
+	for _, c := range retryableCodes {
+		if st.Code() == c {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (w *Worker) getMessages(ctd *callTemplateData, inputData []byte) ([]*dynamic.Message, error) {
 	var inputs []*dynamic.Message
 
-	if w.cachedMessages != nil {
+	// Binary payloads sourced from a DataProvider vary per call (each
+	// worker atomically claims the next row/line), so they must never be
+	// served from the cache below.
+	if w.cachedMessages != nil && w.dataProvider == nil {
 		return w.cachedMessages, nil
 	}
 
@@ -161,13 +337,15 @@ func (w *Worker) getMessages(ctd *callTemplateData, inputData []byte) ([]*dynami
 			return nil, err
 		}
 
-		w.cachedMessages = inputs
+		if w.dataProvider == nil {
+			w.cachedMessages = inputs
+		}
 	}
 
 	return inputs, nil
 }
 
-func (w *Worker) makeClientStreamingRequest(ctx *context.Context, input []*dynamic.Message) error {
+func (w *Worker) makeClientStreamingRequest(ctx *context.Context, ctd *callTemplateData, input []*dynamic.Message) error {
 	str, err := w.stub.InvokeRpcClientStream(*ctx, w.mtd)
 	counter := 0
 	// TODO: need to handle and propagate errors
@@ -175,13 +353,21 @@ func (w *Worker) makeClientStreamingRequest(ctx *context.Context, input []*dynam
 		inputLen := len(input)
 		if input == nil || inputLen == 0 {
 			// TODO: need to handle error
-			_, _ = str.CloseAndReceive()
+			if resp, _ := str.CloseAndReceive(); resp != nil {
+				if dm, ok := resp.(*dynamic.Message); ok {
+					w.observeMessage(ctd, dm)
+				}
+			}
 			break
 		}
 
 		if counter == inputLen {
 			// TODO: need to handle error
-			_, _ = str.CloseAndReceive()
+			if resp, _ := str.CloseAndReceive(); resp != nil {
+				if dm, ok := resp.(*dynamic.Message); ok {
+					w.observeMessage(ctd, dm)
+				}
+			}
 			break
 		}
 
@@ -198,7 +384,11 @@ func (w *Worker) makeClientStreamingRequest(ctx *context.Context, input []*dynam
 			// We get EOF on send if the server says "go away"
 			// We have to use CloseAndReceive to get the actual code
 			// TODO: need to handle error
-			_, _ = str.CloseAndReceive()
+			if resp, _ := str.CloseAndReceive(); resp != nil {
+				if dm, ok := resp.(*dynamic.Message); ok {
+					w.observeMessage(ctd, dm)
+				}
+			}
 			break
 		}
 		counter++
@@ -206,23 +396,25 @@ func (w *Worker) makeClientStreamingRequest(ctx *context.Context, input []*dynam
 	return nil
 }
 
-func (w *Worker) makeServerStreamingRequest(ctx *context.Context, input *dynamic.Message) error {
+func (w *Worker) makeServerStreamingRequest(ctx *context.Context, ctd *callTemplateData, input *dynamic.Message) error {
 	str, err := w.stub.InvokeRpcServerStream(*ctx, w.mtd, input)
 	// TODO: need to handle and propagate errors
 	for err == nil {
-		_, err = str.RecvMsg()
+		var msg *dynamic.Message
+		msg, err = str.RecvMsg()
 		if err != nil {
 			if err == io.EOF {
 				err = nil
 			}
 			break
 		}
+		w.observeMessage(ctd, msg)
 	}
 
 	return err
 }
 
-func (w *Worker) makeBidiRequest(ctx *context.Context, input []*dynamic.Message) error {
+func (w *Worker) makeBidiRequest(ctx *context.Context, ctd *callTemplateData, input []*dynamic.Message) error {
 	str, err := w.stub.InvokeRpcBidiStream(*ctx, w.mtd)
 	if err != nil {
 		return err
@@ -242,12 +434,13 @@ func (w *Worker) makeBidiRequest(ctx *context.Context, input []*dynamic.Message)
 
 	go func() {
 		for {
-			_, err := str.RecvMsg()
+			msg, err := str.RecvMsg()
 
 			if err != nil {
 				close(recvDone)
 				break
 			}
+			w.observeMessage(ctd, msg)
 		}
 	}()
 
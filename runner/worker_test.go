@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/bojand/ghz/protodesc"
+)
+
+// fakeChannel implements grpcdynamic.Channel without a real connection, so
+// Worker.makeRequest can be driven end-to-end against a scripted sequence
+// of responses/errors.
+type fakeChannel struct {
+	invoke    func(ctx context.Context, attempt int) error
+	callCount int32
+}
+
+func (f *fakeChannel) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	attempt := int(atomic.AddInt32(&f.callCount, 1))
+
+	if err := f.invoke(ctx, attempt); err != nil {
+		return err
+	}
+
+	if resp, ok := reply.(*dynamic.Message); ok {
+		return resp.TrySetFieldByName("message", "hello")
+	}
+
+	return nil
+}
+
+func (f *fakeChannel) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	panic("not implemented")
+}
+
+func newTestWorker(t *testing.T, c *RunConfig, invoke func(ctx context.Context, attempt int) error) (*Worker, *retryRecorder) {
+	t.Helper()
+
+	md, err := protodesc.GetMethodDescFromProto("helloworld.Greeter/SayHello", "../testdata/greeter.proto", []string{})
+	assert.NoError(t, err)
+
+	var reqCounter int64
+	retries := newRetryRecorder()
+
+	return &Worker{
+		stub:          grpcdynamic.NewStub(&fakeChannel{invoke: invoke}),
+		mtd:           md,
+		config:        c,
+		stopCh:        make(chan bool),
+		reqCounter:    &reqCounter,
+		nReq:          1,
+		workerID:      "worker-test",
+		retryRecorder: retries,
+	}, retries
+}
+
+func TestWorker_MakeRequest_Success(t *testing.T) {
+	w, retries := newTestWorker(t, &RunConfig{data: []byte(`{"name":"world"}`)}, func(ctx context.Context, attempt int) error {
+		return nil
+	})
+
+	err := w.makeRequest()
+	assert.NoError(t, err)
+
+	attempts, intermediate, terminal := retries.Snapshot()
+	assert.Equal(t, map[int64]int64{1: 1}, attempts)
+	assert.Equal(t, int64(0), intermediate)
+	assert.Equal(t, int64(0), terminal)
+}
+
+func TestWorker_MakeRequest_RetriesThenSucceeds(t *testing.T) {
+	c := &RunConfig{
+		data:        []byte(`{"name":"world"}`),
+		metadata:    []byte(`{"x-attempt":"{{.AttemptNumber}}"}`),
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Second,
+		Multiplier:  1,
+	}
+
+	var seenAttempts []string
+	w, retries := newTestWorker(t, c, func(ctx context.Context, attempt int) error {
+		if md, ok := metadata.FromOutgoingContext(ctx); ok {
+			seenAttempts = append(seenAttempts, md.Get("x-attempt")[0])
+		}
+		if attempt < 3 {
+			return status.Error(codes.Unavailable, "try again")
+		}
+		return nil
+	})
+
+	err := w.makeRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, seenAttempts)
+
+	attempts, intermediate, terminal := retries.Snapshot()
+	assert.Equal(t, map[int64]int64{1: 1, 2: 1, 3: 1}, attempts)
+	assert.Equal(t, int64(2), intermediate)
+	assert.Equal(t, int64(0), terminal)
+}
+
+func TestWorker_MakeRequest_ExhaustsRetries(t *testing.T) {
+	c := &RunConfig{
+		data:        []byte(`{"name":"world"}`),
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    time.Second,
+		Multiplier:  1,
+	}
+
+	w, retries := newTestWorker(t, c, func(ctx context.Context, attempt int) error {
+		return status.Error(codes.Unavailable, "down")
+	})
+
+	err := w.makeRequest()
+	assert.Error(t, err)
+
+	attempts, intermediate, terminal := retries.Snapshot()
+	assert.Equal(t, map[int64]int64{1: 1, 2: 1}, attempts)
+	assert.Equal(t, int64(1), intermediate)
+	assert.Equal(t, int64(1), terminal)
+}
+
+func TestWorker_MakeRequest_Assertions(t *testing.T) {
+	c := &RunConfig{
+		data: []byte(`{"name":"world"}`),
+		Assertions: []Assertion{
+			{Name: "has-message", Expr: `{{eq .reply.message "hello"}}`},
+			{Name: "wrong-message", Expr: `{{eq .reply.message "bye"}}`},
+		},
+	}
+
+	w, _ := newTestWorker(t, c, func(ctx context.Context, attempt int) error {
+		return nil
+	})
+	w.assertions = c.Assertions
+	w.assertionRecorder = newAssertionRecorder()
+
+	err := w.makeRequest()
+	assert.NoError(t, err)
+
+	snapshot := w.assertionRecorder.Snapshot()
+	assert.Equal(t, AssertionCount{Passed: 1, Failed: 0}, snapshot["has-message"])
+	assert.Equal(t, AssertionCount{Passed: 0, Failed: 1}, snapshot["wrong-message"])
+}